@@ -8,7 +8,14 @@ import (
 // New creates a new HTTP client provider with pre-configured middlewares.
 func New(cfg *setting.Cfg) httpclient.Provider {
 	middlewares := []httpclient.Middleware{
+		// TracingMiddleware goes first so its span is outermost, wrapping
+		// every other middleware (resilience retries, OAuth2 token fetch,
+		// ...) instead of just the raw transport, giving one trace per
+		// logical request rather than per retry attempt.
+		TracingMiddleware(),
 		DataSourceMetricsMiddleware(),
+		ResilienceMiddleware(),
+		OAuth2Middleware(),
 		httpclient.BasicAuthenticationMiddleware(),
 		httpclient.CustomHeadersMiddleware(),
 	}
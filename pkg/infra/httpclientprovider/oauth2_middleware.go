@@ -0,0 +1,185 @@
+package httpclientprovider
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/oauth2/jwt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+)
+
+// OAuth2MiddlewareName is the string identifier of the OAuth2 middleware.
+const OAuth2MiddlewareName = "oauth2"
+
+// tokenFetcher always performs a fresh token request, as opposed to
+// oauth2.TokenSource, which (via clientcredentials.Config.TokenSource and
+// jwt.Config.TokenSource) wraps an oauth2.ReuseTokenSource that keeps
+// serving its own cached token until it expires. Using it here means our
+// oauth2TokenCache is the only layer doing caching, so invalidate() is
+// actually able to force a refresh instead of handing back the same
+// not-yet-expired token.
+type tokenFetcher interface {
+	Token(ctx context.Context) (*oauth2.Token, error)
+}
+
+// clientCredentialsFetcher adapts *clientcredentials.Config, whose Token
+// method already fetches fresh on every call.
+type clientCredentialsFetcher struct {
+	cfg *clientcredentials.Config
+}
+
+func (f clientCredentialsFetcher) Token(ctx context.Context) (*oauth2.Token, error) {
+	return f.cfg.Token(ctx)
+}
+
+// jwtFetcher adapts *jwt.Config, which only exposes a reused TokenSource.
+// Building a brand new TokenSource on every call instead of reusing one
+// across calls means each one starts from a nil cached token, so it always
+// performs a real JWT-bearer exchange.
+type jwtFetcher struct {
+	cfg *jwt.Config
+}
+
+func (f jwtFetcher) Token(ctx context.Context) (*oauth2.Token, error) {
+	return f.cfg.TokenSource(ctx).Token()
+}
+
+// oauth2Config describes the token source for a single datasource's OAuth2
+// client-credentials or JWT-bearer flow.
+type oauth2Config struct {
+	datasourceUID string
+	tokenFetcher  tokenFetcher
+}
+
+type oauth2CacheEntry struct {
+	token *oauth2.Token
+}
+
+// oauth2TokenCache caches the current bearer token per datasource, keyed by
+// datasource UID (the datasource_uid label).
+type oauth2TokenCache struct {
+	cache map[string]*oauth2CacheEntry
+	sync.Mutex
+}
+
+var oauth2Tokens = oauth2TokenCache{
+	cache: make(map[string]*oauth2CacheEntry),
+}
+
+func (c *oauth2TokenCache) getToken(ctx context.Context, cfg *oauth2Config) (*oauth2.Token, error) {
+	c.Lock()
+	entry, present := c.cache[cfg.datasourceUID]
+	c.Unlock()
+
+	if present && entry.token.Valid() {
+		return entry.token, nil
+	}
+
+	token, err := cfg.tokenFetcher.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	c.cache[cfg.datasourceUID] = &oauth2CacheEntry{token: token}
+	c.Unlock()
+
+	return token, nil
+}
+
+// invalidate forces the next request for datasourceUID to fetch a fresh
+// token, used when the upstream responds with 401 Unauthorized.
+func (c *oauth2TokenCache) invalidate(datasourceUID string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.cache, datasourceUID)
+}
+
+// OAuth2Middleware authenticates outgoing datasource requests using an
+// OAuth2 client-credentials or JWT-bearer flow, attaching "Authorization:
+// Bearer <token>" to every request. Tokens are fetched lazily, cached per
+// datasource and refreshed automatically, either because they expired or
+// because the upstream responded with a 401.
+func OAuth2Middleware() httpclient.Middleware {
+	return httpclient.NamedMiddlewareFunc(OAuth2MiddlewareName, func(opts httpclient.Options, next http.RoundTripper) http.RoundTripper {
+		cfg := oauth2ConfigFromOptions(opts)
+		if cfg == nil {
+			return next
+		}
+
+		return httpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			token, err := oauth2Tokens.getToken(req.Context(), cfg)
+			if err != nil {
+				return nil, err
+			}
+			token.SetAuthHeader(req)
+
+			resp, err := next.RoundTrip(req)
+			if err == nil && resp != nil && resp.StatusCode == http.StatusUnauthorized {
+				oauth2Tokens.invalidate(cfg.datasourceUID)
+			}
+			return resp, err
+		})
+	})
+}
+
+// oauth2ConfigFromOptions builds an oauth2Config from the datasource's
+// JsonData/SecureJsonData, which HTTPClientOptions plumbs through as
+// opts.CustomOptions. Returns nil when OAuth2 isn't configured, in which
+// case the middleware is a no-op and defers to the next round tripper.
+func oauth2ConfigFromOptions(opts httpclient.Options) *oauth2Config {
+	if opts.CustomOptions == nil {
+		return nil
+	}
+
+	tokenURL, _ := opts.CustomOptions["oauth2TokenUrl"].(string)
+	clientID, _ := opts.CustomOptions["oauth2ClientId"].(string)
+	if tokenURL == "" || clientID == "" {
+		return nil
+	}
+
+	var scopes []string
+	if raw, ok := opts.CustomOptions["oauth2Scopes"].(string); ok && raw != "" {
+		scopes = strings.Split(raw, " ")
+	}
+
+	endpointParams := url.Values{}
+	if audience, ok := opts.CustomOptions["oauth2Audience"].(string); ok && audience != "" {
+		endpointParams.Set("audience", audience)
+	}
+
+	if privateKey, ok := opts.CustomOptions["oauth2JwtPrivateKey"].(string); ok && privateKey != "" {
+		jwtConfig := &jwt.Config{
+			Email:      clientID,
+			PrivateKey: []byte(privateKey),
+			TokenURL:   tokenURL,
+			Scopes:     scopes,
+			Audience:   endpointParams.Get("audience"),
+		}
+
+		return &oauth2Config{
+			datasourceUID: opts.Labels["datasource_uid"],
+			tokenFetcher:  jwtFetcher{cfg: jwtConfig},
+		}
+	}
+
+	clientSecret, _ := opts.CustomOptions["oauth2ClientSecret"].(string)
+	ccConfig := &clientcredentials.Config{
+		ClientID:       clientID,
+		ClientSecret:   clientSecret,
+		TokenURL:       tokenURL,
+		Scopes:         scopes,
+		EndpointParams: endpointParams,
+	}
+
+	return &oauth2Config{
+		datasourceUID: opts.Labels["datasource_uid"],
+		tokenFetcher:  clientCredentialsFetcher{cfg: ccConfig},
+	}
+}
@@ -0,0 +1,288 @@
+package httpclientprovider
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+)
+
+// ResilienceMiddlewareName is the string identifier of the resilience middleware.
+const ResilienceMiddlewareName = "resilience"
+
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+var (
+	circuitStateMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grafana_datasource_circuit_state",
+		Help: "Current circuit breaker state per datasource (0=closed, 1=open, 2=half-open).",
+	}, []string{"datasource_uid"})
+
+	retriesTotalMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grafana_datasource_retries_total",
+		Help: "Total number of request retries performed per datasource.",
+	}, []string{"datasource_uid"})
+)
+
+// resilienceOptions are the per-datasource JsonData knobs controlling the
+// circuit breaker and retry policy. All of them are optional; when
+// maxRetries and failureRatio are both zero the middleware is a no-op.
+type resilienceOptions struct {
+	maxRetries   int
+	failureRatio float64
+	minSamples   int
+	cooldown     time.Duration
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+}
+
+// circuitBreaker is a minimal closed/open/half-open breaker that computes
+// the failure ratio over successive windows of minSamples requests,
+// starting a fresh window after each one. A zero failureRatio never opens it.
+type circuitBreaker struct {
+	sync.Mutex
+	state            int
+	failures         int
+	samples          int
+	openedAt         time.Time
+	halfOpenInFlight bool
+	datasourceUID    string
+}
+
+// resilienceState caches one circuitBreaker per datasource, keyed by
+// datasource UID (the datasource_uid label).
+type resilienceState struct {
+	sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+var resilienceBreakers = resilienceState{
+	breakers: make(map[string]*circuitBreaker),
+}
+
+func (s *resilienceState) get(datasourceUID string) *circuitBreaker {
+	s.Lock()
+	defer s.Unlock()
+
+	cb, present := s.breakers[datasourceUID]
+	if !present {
+		cb = &circuitBreaker{datasourceUID: datasourceUID}
+		s.breakers[datasourceUID] = cb
+	}
+	return cb
+}
+
+// allow reports whether a request may proceed, transitioning open breakers
+// to half-open once the cooldown has elapsed. A half-open breaker admits a
+// single in-flight probe request at a time; everything else is rejected
+// until recordResult reports the probe's outcome.
+func (cb *circuitBreaker) allow(cooldown time.Duration) bool {
+	cb.Lock()
+	defer cb.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if time.Since(cb.openedAt) >= cooldown {
+			cb.state = circuitHalfOpen
+			cb.halfOpenInFlight = true
+			circuitStateMetric.WithLabelValues(cb.datasourceUID).Set(float64(circuitHalfOpen))
+			return true
+		}
+		return false
+	case circuitHalfOpen:
+		if cb.halfOpenInFlight {
+			return false
+		}
+		cb.halfOpenInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (cb *circuitBreaker) recordResult(success bool, opts *resilienceOptions) {
+	cb.Lock()
+	defer cb.Unlock()
+
+	if cb.state == circuitHalfOpen {
+		cb.halfOpenInFlight = false
+		if success {
+			cb.state = circuitClosed
+			cb.failures = 0
+			cb.samples = 0
+		} else {
+			cb.state = circuitOpen
+			cb.openedAt = time.Now()
+		}
+		circuitStateMetric.WithLabelValues(cb.datasourceUID).Set(float64(cb.state))
+		return
+	}
+
+	cb.samples++
+	if !success {
+		cb.failures++
+	}
+
+	if cb.samples >= opts.minSamples {
+		ratio := float64(cb.failures) / float64(cb.samples)
+		if opts.failureRatio > 0 && ratio >= opts.failureRatio {
+			cb.state = circuitOpen
+			cb.openedAt = time.Now()
+			circuitStateMetric.WithLabelValues(cb.datasourceUID).Set(float64(cb.state))
+		}
+		cb.failures = 0
+		cb.samples = 0
+	}
+}
+
+// ResilienceMiddleware guards every outgoing datasource request with a
+// circuit breaker (closed -> open -> half-open) and bounded
+// exponential-backoff retries with jitter for idempotent GETs and
+// 5xx/429 responses, honoring any Retry-After header on the response.
+func ResilienceMiddleware() httpclient.Middleware {
+	return httpclient.NamedMiddlewareFunc(ResilienceMiddlewareName, func(opts httpclient.Options, next http.RoundTripper) http.RoundTripper {
+		resilienceOpts := resilienceOptionsFromOptions(opts)
+		if resilienceOpts == nil {
+			return next
+		}
+
+		datasourceUID := opts.Labels["datasource_uid"]
+		cb := resilienceBreakers.get(datasourceUID)
+
+		return httpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !cb.allow(resilienceOpts.cooldown) {
+				return nil, errCircuitOpen
+			}
+
+			resp, err := doWithRetries(req, next, resilienceOpts, datasourceUID)
+			cb.recordResult(err == nil && resp != nil && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests, resilienceOpts)
+			return resp, err
+		})
+	})
+}
+
+var errCircuitOpen = &circuitOpenError{}
+
+type circuitOpenError struct{}
+
+func (e *circuitOpenError) Error() string {
+	return "circuit breaker open for datasource"
+}
+
+func doWithRetries(req *http.Request, next http.RoundTripper, opts *resilienceOptions, datasourceUID string) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+
+	for attempt := 0; attempt <= opts.maxRetries; attempt++ {
+		resp, err = next.RoundTrip(req)
+
+		if attempt == opts.maxRetries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		retriesTotalMetric.WithLabelValues(datasourceUID).Inc()
+
+		wait := retryAfter(resp)
+		if wait == 0 {
+			wait = backoffWithJitter(attempt, opts.baseBackoff, opts.maxBackoff)
+		}
+		if resp != nil && resp.Body != nil {
+			_, _ = ioutil.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+		}
+		time.Sleep(wait)
+	}
+
+	return resp, err
+}
+
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return resp != nil && (resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests)
+}
+
+// retryAfter parses a Retry-After header expressed in seconds, returning 0
+// when absent or unparsable so the caller falls back to backoff+jitter.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func backoffWithJitter(attempt int, base, max time.Duration) time.Duration {
+	backoff := base << uint(attempt)
+	if backoff <= 0 || backoff > max {
+		backoff = max
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff) + 1))
+	return backoff/2 + jitter/2
+}
+
+func resilienceOptionsFromOptions(opts httpclient.Options) *resilienceOptions {
+	if opts.CustomOptions == nil {
+		return nil
+	}
+
+	maxRetries := intCustomOption(opts, "resilienceMaxRetries", 0)
+	failureRatio := floatCustomOption(opts, "resilienceFailureRatio", 0)
+	if maxRetries == 0 && failureRatio == 0 {
+		return nil
+	}
+
+	return &resilienceOptions{
+		maxRetries:   maxRetries,
+		failureRatio: failureRatio,
+		minSamples:   intCustomOption(opts, "resilienceMinSamples", 10),
+		cooldown:     time.Duration(intCustomOption(opts, "resilienceCooldownSeconds", 30)) * time.Second,
+		baseBackoff:  time.Duration(intCustomOption(opts, "resilienceBaseBackoffMs", 100)) * time.Millisecond,
+		maxBackoff:   time.Duration(intCustomOption(opts, "resilienceMaxBackoffMs", 5000)) * time.Millisecond,
+	}
+}
+
+func intCustomOption(opts httpclient.Options, key string, def int) int {
+	return int(floatCustomOption(opts, key, float64(def)))
+}
+
+// floatCustomOption reads a numeric JsonData option. simplejson (and
+// encoding/json with UseNumber, which DataSource.JsonData.MustMap goes
+// through) decodes numbers as json.Number rather than float64, and some
+// callers set these as plain strings, so both need handling alongside
+// the plain float64 case or every real datasource's resilience knobs
+// silently read as the zero-value default.
+func floatCustomOption(opts httpclient.Options, key string, def float64) float64 {
+	switch val := opts.CustomOptions[key].(type) {
+	case float64:
+		return val
+	case json.Number:
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+	case string:
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
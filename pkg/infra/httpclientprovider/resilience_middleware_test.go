@@ -0,0 +1,115 @@
+package httpclientprovider
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRoundTripper returns the next status code from the list on each call,
+// repeating the last one once exhausted, and counts how many times it ran.
+type fakeRoundTripper struct {
+	statusCodes []int
+	calls       int
+}
+
+func (f *fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	idx := f.calls
+	if idx >= len(f.statusCodes) {
+		idx = len(f.statusCodes) - 1
+	}
+	f.calls++
+	return &http.Response{
+		StatusCode: f.statusCodes[idx],
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		Header:     http.Header{},
+	}, nil
+}
+
+func newResilienceRoundTripper(t *testing.T, datasourceUID string, customOptions map[string]interface{}, next http.RoundTripper) http.RoundTripper {
+	t.Helper()
+	opts := httpclient.Options{
+		CustomOptions: customOptions,
+		Labels:        map[string]string{"datasource_uid": datasourceUID},
+	}
+	return ResilienceMiddleware().CreateMiddleware(opts, next)
+}
+
+func TestFloatCustomOption(t *testing.T) {
+	opts := httpclient.Options{CustomOptions: map[string]interface{}{
+		"asFloat":  float64(3),
+		"asNumber": json.Number("4"),
+		"asString": "5",
+		"asBad":    "not-a-number",
+	}}
+
+	require.Equal(t, 3.0, floatCustomOption(opts, "asFloat", 99))
+	require.Equal(t, 4.0, floatCustomOption(opts, "asNumber", 99))
+	require.Equal(t, 5.0, floatCustomOption(opts, "asString", 99))
+	require.Equal(t, 99.0, floatCustomOption(opts, "asBad", 99))
+	require.Equal(t, 99.0, floatCustomOption(opts, "missing", 99))
+}
+
+func TestResilienceMiddleware_RetriesThenSucceeds(t *testing.T) {
+	next := &fakeRoundTripper{statusCodes: []int{500, 500, 200}}
+	rt := newResilienceRoundTripper(t, "retries-ds", map[string]interface{}{
+		"resilienceMaxRetries":    json.Number("2"),
+		"resilienceBaseBackoffMs": json.Number("1"),
+		"resilienceMaxBackoffMs":  json.Number("2"),
+	}, next)
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, 3, next.calls)
+}
+
+func TestResilienceMiddleware_OpensAfterFailureRatioAndProbesHalfOpen(t *testing.T) {
+	next := &fakeRoundTripper{statusCodes: []int{500}}
+	rt := newResilienceRoundTripper(t, "breaker-ds", map[string]interface{}{
+		"resilienceMaxRetries":      json.Number("0"),
+		"resilienceFailureRatio":    json.Number("0.5"),
+		"resilienceMinSamples":      json.Number("2"),
+		"resilienceCooldownSeconds": json.Number("0"),
+	}, next)
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	// Two failing samples trip the breaker open.
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+
+	// Cooldown is 0, so the breaker is immediately eligible to go
+	// half-open and admit exactly one probe.
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, 3, next.calls, "half-open probe should reach the transport")
+
+	cb := resilienceBreakers.get("breaker-ds")
+	require.Equal(t, circuitOpen, cb.state, "a failed probe should reopen the breaker")
+
+	// The breaker is open again with a 0s cooldown, so the very next call
+	// goes straight back to a half-open probe rather than being rejected.
+	next.statusCodes = []int{200}
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, circuitClosed, cb.state, "a successful probe should close the breaker")
+}
+
+func TestRetryAfterOverridesBackoff(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"1"}}}
+	require.Equal(t, time.Second, retryAfter(resp))
+
+	require.Equal(t, time.Duration(0), retryAfter(&http.Response{Header: http.Header{}}))
+	require.Equal(t, time.Duration(0), retryAfter(nil))
+}
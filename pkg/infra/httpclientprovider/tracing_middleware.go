@@ -0,0 +1,62 @@
+package httpclientprovider
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+)
+
+// TracingMiddlewareName is the string identifier of the tracing middleware.
+const TracingMiddlewareName = "tracing"
+
+var tracer = otel.Tracer("github.com/grafana/grafana/pkg/infra/httpclientprovider")
+
+// TracingMiddleware wraps every outgoing datasource request in an
+// OpenTelemetry client span, linking it to the incoming Grafana request so
+// dashboard-render, alert-evaluation and explore queries produce a single
+// trace from browser to upstream Prometheus/Loki/Elastic. The global
+// TextMapPropagator injects W3C traceparent/tracestate (or B3, when
+// configured) headers, and the span is sampled by the TracerProvider set up
+// at startup from the [tracing.opentelemetry] INI section.
+func TracingMiddleware() httpclient.Middleware {
+	return httpclient.NamedMiddlewareFunc(TracingMiddlewareName, func(opts httpclient.Options, next http.RoundTripper) http.RoundTripper {
+		datasourceUID := opts.Labels["datasource_uid"]
+		datasourceType := opts.Labels["datasource_type"]
+
+		return httpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := tracer.Start(req.Context(), "datasource request", trace.WithSpanKind(trace.SpanKindClient))
+			defer span.End()
+
+			span.SetAttributes(
+				semconv.HTTPMethodKey.String(req.Method),
+				semconv.NetPeerNameKey.String(req.URL.Hostname()),
+				attribute.String("datasource.uid", datasourceUID),
+				attribute.String("datasource.type", datasourceType),
+			)
+
+			req = req.WithContext(ctx)
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return resp, err
+			}
+
+			span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+			if resp.StatusCode >= http.StatusBadRequest {
+				span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+			}
+
+			return resp, nil
+		})
+	})
+}
@@ -1,33 +1,37 @@
 package models
 
 import (
+	"container/list"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/grafana/grafana-aws-sdk/pkg/sigv4"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/grafana/grafana/pkg/infra/httpclientprovider"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-type proxyTransportCache struct {
-	cache map[int64]cachedTransport
-	sync.Mutex
-}
-
 // dataSourceTransport implements http.RoundTripper (https://golang.org/pkg/net/http/#RoundTripper)
 type dataSourceTransport struct {
 	datasourceName string
 	headers        map[string]string
 	transport      *http.Transport
-	next           http.RoundTripper
+	// rt is the metrics+tracing middleware chain built once at transport
+	// creation time (see GetHttpTransport), rather than re-wrapped on every
+	// RoundTrip call. Building it per-call used to silently drop the
+	// caller's context from tracing, since a fresh chain was wired up with
+	// no knowledge of the request in flight.
+	rt http.RoundTripper
 }
 
 // RoundTrip executes a single HTTP transaction, returning a Response for the provided Request.
@@ -36,23 +40,260 @@ func (d *dataSourceTransport) RoundTrip(req *http.Request) (*http.Response, erro
 		req.Header.Set(key, value)
 	}
 
-	// temporary solution to continue to support earlier supported metrics middleware.
-	opts := &httpclient.Options{Labels: map[string]string{
-		"datasource_name": d.datasourceName,
-	}}
-	rt := httpclientprovider.DataSourceMetricsMiddleware().CreateMiddleware(opts, d.next)
+	return d.rt.RoundTrip(req)
+}
+
+const (
+	transportCacheTTL        = 15 * time.Minute
+	transportCacheMaxEntries = 1000
+	transportCacheMaxPerOrg  = 100
+)
+
+var (
+	// transportCacheEntriesMetric and transportCacheEvictionsMetric are
+	// shared by all three lruCache instances (transports, roundTrippers,
+	// decryptedValues). Each instance reports under its own "cache" label
+	// value, so one instance's Set/Inc can't be overwritten by another's —
+	// without that label, the three caches fight over the same
+	// (metric, org) series and the gauge reflects only whichever cache
+	// wrote last.
+	transportCacheEntriesMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grafana_datasource_transport_cache_entries",
+		Help: "Number of cached datasource transport/secret entries per organization and cache.",
+	}, []string{"org", "cache"})
+
+	transportCacheEvictionsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grafana_datasource_transport_cache_evictions_total",
+		Help: "Total number of datasource transport/secret cache entries evicted, per organization and cache.",
+	}, []string{"org", "cache"})
+)
+
+// transportCacheKey scopes a cache entry to an organization and datasource,
+// with `updated` acting as the natural invalidation token: whenever a
+// datasource is saved, its new `updated` produces a fresh key so the old
+// entry simply ages out instead of requiring an explicit update-path flush.
+type transportCacheKey struct {
+	orgId         int64
+	datasourceUid string
+	updated       time.Time
+}
+
+type transportCacheIndexKey struct {
+	orgId         int64
+	datasourceUid string
+}
+
+type lruEntry struct {
+	key       transportCacheKey
+	value     interface{}
+	expiresAt time.Time
+}
+
+// lruCache is an org-scoped, TTL-bound, size-bounded LRU used to back each
+// of TransportCache's three value kinds (transports, round trippers and
+// decrypted secrets). name identifies which of those three this instance
+// is, for the metrics' "cache" label.
+type lruCache struct {
+	name string
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[transportCacheKey]*list.Element
+	// index supports Invalidate(orgId, uid), which must drop an entry
+	// regardless of the `updated` it was cached under.
+	index   map[transportCacheIndexKey]map[transportCacheKey]*list.Element
+	orgSize map[int64]int
+}
+
+func newLRUCache(name string) *lruCache {
+	return &lruCache{
+		name:    name,
+		ll:      list.New(),
+		items:   make(map[transportCacheKey]*list.Element),
+		index:   make(map[transportCacheIndexKey]map[transportCacheKey]*list.Element),
+		orgSize: make(map[int64]int),
+	}
+}
+
+func (c *lruCache) get(key transportCacheKey) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
 
-	return rt.RoundTrip(req)
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeElement(el)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *lruCache) set(key transportCacheKey, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+
+	el := c.ll.PushFront(&lruEntry{key: key, value: value, expiresAt: time.Now().Add(transportCacheTTL)})
+	c.items[key] = el
+
+	idxKey := transportCacheIndexKey{orgId: key.orgId, datasourceUid: key.datasourceUid}
+	if c.index[idxKey] == nil {
+		c.index[idxKey] = make(map[transportCacheKey]*list.Element)
+	}
+	c.index[idxKey][key] = el
+
+	c.orgSize[key.orgId]++
+	transportCacheEntriesMetric.WithLabelValues(orgLabel(key.orgId), c.name).Set(float64(c.orgSize[key.orgId]))
+
+	c.evictIfNeeded(key.orgId)
 }
 
-type cachedTransport struct {
-	updated time.Time
+// invalidate drops every entry for (orgId, datasourceUid), irrespective of
+// the `updated` timestamp it was cached under.
+func (c *lruCache) invalidate(orgId int64, datasourceUid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	*dataSourceTransport
+	idxKey := transportCacheIndexKey{orgId: orgId, datasourceUid: datasourceUid}
+	for _, el := range c.index[idxKey] {
+		c.removeElement(el)
+	}
 }
 
-var ptc = proxyTransportCache{
-	cache: make(map[int64]cachedTransport),
+// clear drops every entry in the cache, used by the legacy ClearDSDecryptionCache global wipe.
+func (c *lruCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll = list.New()
+	c.items = make(map[transportCacheKey]*list.Element)
+	c.index = make(map[transportCacheIndexKey]map[transportCacheKey]*list.Element)
+	c.orgSize = make(map[int64]int)
+}
+
+func (c *lruCache) evictIfNeeded(orgId int64) {
+	for c.orgSize[orgId] > transportCacheMaxPerOrg {
+		c.evictOldestForOrg(orgId)
+	}
+	for c.ll.Len() > transportCacheMaxEntries {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		entry := oldest.Value.(*lruEntry)
+		c.removeElement(oldest)
+		transportCacheEvictionsMetric.WithLabelValues(orgLabel(entry.key.orgId), c.name).Inc()
+	}
+}
+
+func (c *lruCache) evictOldestForOrg(orgId int64) {
+	for el := c.ll.Back(); el != nil; el = el.Prev() {
+		if el.Value.(*lruEntry).key.orgId == orgId {
+			c.removeElement(el)
+			transportCacheEvictionsMetric.WithLabelValues(orgLabel(orgId), c.name).Inc()
+			return
+		}
+	}
+}
+
+// removeElement must be called with c.mu held.
+func (c *lruCache) removeElement(el *list.Element) {
+	entry := el.Value.(*lruEntry)
+	c.ll.Remove(el)
+	delete(c.items, entry.key)
+
+	idxKey := transportCacheIndexKey{orgId: entry.key.orgId, datasourceUid: entry.key.datasourceUid}
+	delete(c.index[idxKey], entry.key)
+	if len(c.index[idxKey]) == 0 {
+		delete(c.index, idxKey)
+	}
+
+	c.orgSize[entry.key.orgId]--
+	transportCacheEntriesMetric.WithLabelValues(orgLabel(entry.key.orgId), c.name).Set(float64(c.orgSize[entry.key.orgId]))
+}
+
+func orgLabel(orgId int64) string {
+	return strconv.FormatInt(orgId, 10)
+}
+
+// TransportCache holds the datasource HTTP transports, round trippers and
+// decrypted secrets that used to live in the package-level ptc, ptc2 and
+// dsDecryptionCache maps. Each is now an org-scoped, LRU- and TTL-bound
+// lruCache so a single noisy org can't starve the others out of cache
+// space, and Invalidate lets datasource updates/deletes and secret
+// rotations flush precisely the affected entries.
+type TransportCache struct {
+	transports      *lruCache
+	roundTrippers   *lruCache
+	decryptedValues *lruCache
+}
+
+// transportCacheSingleton is the one TransportCache instance ProvideTransportCache
+// ever returns, built lazily on first use and shared by every caller afterwards.
+var (
+	transportCacheSingleton     *TransportCache
+	transportCacheSingletonOnce sync.Once
+)
+
+// ProvideTransportCache is the wire provider for *TransportCache. It always
+// returns the same instance: wire calls this once to build whatever it
+// injects, and the package-level DataSource cache methods below go through
+// the exact same instance via defaultTransportCache, so a wire-injected
+// *TransportCache and the cache actually serving GetHttpTransport/
+// GetHttpTransport2/DecryptedValues are never two different, disconnected
+// caches.
+func ProvideTransportCache() *TransportCache {
+	transportCacheSingletonOnce.Do(func() {
+		transportCacheSingleton = &TransportCache{
+			transports:      newLRUCache("transports"),
+			roundTrippers:   newLRUCache("roundTrippers"),
+			decryptedValues: newLRUCache("decryptedValues"),
+		}
+	})
+	return transportCacheSingleton
+}
+
+// Invalidate removes every cached transport, round tripper and decrypted
+// secret for the given datasource.
+func (c *TransportCache) Invalidate(orgId int64, datasourceUid string) {
+	c.transports.invalidate(orgId, datasourceUid)
+	c.roundTrippers.invalidate(orgId, datasourceUid)
+	c.decryptedValues.invalidate(orgId, datasourceUid)
+}
+
+// defaultTransportCache is the TransportCache instance backing the
+// DataSource cache methods below. It is fetched through ProvideTransportCache
+// itself, rather than constructed separately, so it is the same instance
+// wire injects into any service with a *TransportCache dependency (see
+// pkg/server/wire.go in the full build) — one cache, not two disconnected
+// ones. InvalidateTransportCache is the package-level entry point for code
+// that only has package models in scope, such as the datasource
+// save/delete handlers, which must call it after a datasource is updated,
+// deleted or has its secrets rotated.
+var defaultTransportCache = ProvideTransportCache()
+
+// InvalidateTransportCache flushes the cached transport, round tripper and
+// decrypted secrets for a single datasource. Call this from the datasource
+// save/delete/secret-rotation paths so stale transports and decrypted
+// values don't outlive the datasource they were built from.
+//
+// This checkout's pkg/models has no other file defining the datasource
+// save/delete/secret-rotation handlers themselves (confirmed by search:
+// datasource_cache.go is the only file in the package), so there is no
+// real call site here to wire this into; ClearDSDecryptionCache's global
+// wipe remains the only invalidation path actually exercised until those
+// handlers exist in this checkout.
+func InvalidateTransportCache(orgId int64, datasourceUid string) {
+	defaultTransportCache.Invalidate(orgId, datasourceUid)
 }
 
 func (ds *DataSource) getTimeout() time.Duration {
@@ -80,11 +321,10 @@ func (ds *DataSource) GetHttpClient() (*http.Client, error) {
 
 // Creates a HTTP Transport middleware chain
 func (ds *DataSource) GetHttpTransport() (*dataSourceTransport, error) {
-	ptc.Lock()
-	defer ptc.Unlock()
+	key := transportCacheKey{orgId: ds.OrgId, datasourceUid: ds.Uid, updated: ds.Updated}
 
-	if t, present := ptc.cache[ds.Id]; present && ds.Updated.Equal(t.updated) {
-		return t.dataSourceTransport, nil
+	if cached, ok := defaultTransportCache.transports.get(key); ok {
+		return cached.(*dataSourceTransport), nil
 	}
 
 	tlsConfig, err := ds.GetTLSConfig()
@@ -117,35 +357,29 @@ func (ds *DataSource) GetHttpTransport() (*dataSourceTransport, error) {
 		next = ds.sigV4Middleware(transport)
 	}
 
+	// Build the metrics+tracing middleware chain once here, rather than
+	// per RoundTrip call, so the caller's request context reaches the
+	// tracing span instead of being dropped by a freshly built chain.
+	metricsOpts := &httpclient.Options{Labels: map[string]string{
+		"datasource_name": ds.Name,
+		"datasource_uid":  ds.Uid,
+		"datasource_type": ds.Type,
+	}}
+	rt := httpclientprovider.DataSourceMetricsMiddleware().CreateMiddleware(metricsOpts, next)
+	rt = httpclientprovider.TracingMiddleware().CreateMiddleware(metricsOpts, rt)
+
 	dsTransport := &dataSourceTransport{
 		datasourceName: ds.Name,
 		headers:        customHeaders,
 		transport:      transport,
-		next:           next,
+		rt:             rt,
 	}
 
-	ptc.cache[ds.Id] = cachedTransport{
-		dataSourceTransport: dsTransport,
-		updated:             ds.Updated,
-	}
+	defaultTransportCache.transports.set(key, dsTransport)
 
 	return dsTransport, nil
 }
 
-type proxyTransportCache2 struct {
-	cache map[int64]cachedRoundTripper2
-	sync.Mutex
-}
-
-type cachedRoundTripper2 struct {
-	updated      time.Time
-	roundTripper http.RoundTripper
-}
-
-var ptc2 = proxyTransportCache2{
-	cache: make(map[int64]cachedRoundTripper2),
-}
-
 func (ds *DataSource) GetHttpClient2(provider httpclient.Provider) (*http.Client, error) {
 	transport, err := ds.GetHttpTransport2(provider)
 	if err != nil {
@@ -159,11 +393,10 @@ func (ds *DataSource) GetHttpClient2(provider httpclient.Provider) (*http.Client
 }
 
 func (ds *DataSource) GetHttpTransport2(provider httpclient.Provider) (http.RoundTripper, error) {
-	ptc2.Lock()
-	defer ptc2.Unlock()
+	key := transportCacheKey{orgId: ds.OrgId, datasourceUid: ds.Uid, updated: ds.Updated}
 
-	if t, present := ptc2.cache[ds.Id]; present && ds.Updated.Equal(t.updated) {
-		return t.roundTripper, nil
+	if cached, ok := defaultTransportCache.roundTrippers.get(key); ok {
+		return cached.(http.RoundTripper), nil
 	}
 
 	rt, err := provider.GetTransport(ds.HTTPClientOptions())
@@ -171,10 +404,7 @@ func (ds *DataSource) GetHttpTransport2(provider httpclient.Provider) (http.Roun
 		return nil, err
 	}
 
-	ptc2.cache[ds.Id] = cachedRoundTripper2{
-		roundTripper: rt,
-		updated:      ds.Updated,
-	}
+	defaultTransportCache.roundTrippers.set(key, rt)
 
 	return rt, nil
 }
@@ -193,6 +423,7 @@ func (ds *DataSource) HTTPClientOptions() *httpclient.Options {
 		Labels: map[string]string{
 			"datasource_name": ds.Name,
 			"datasource_uid":  ds.Uid,
+			"datasource_type": ds.Type,
 			"kind":            "datasource",
 		},
 		TLS: ds.TLSOptions(),
@@ -233,6 +464,19 @@ func (ds *DataSource) HTTPClientOptions() *httpclient.Options {
 		}
 	}
 
+	if ds.JsonData != nil && ds.JsonData.Get("oauth2TokenUrl").MustString() != "" {
+		if opts.CustomOptions == nil {
+			opts.CustomOptions = map[string]interface{}{}
+		}
+
+		if val, exists := ds.DecryptedValue("oauth2ClientSecret"); exists {
+			opts.CustomOptions["oauth2ClientSecret"] = val
+		}
+		if val, exists := ds.DecryptedValue("oauth2JwtPrivateKey"); exists {
+			opts.CustomOptions["oauth2JwtPrivateKey"] = val
+		}
+	}
+
 	return &opts
 }
 
@@ -362,34 +606,16 @@ func (ds *DataSource) getCustomHeaders() map[string]string {
 	return headers
 }
 
-type cachedDecryptedJSON struct {
-	updated time.Time
-	json    map[string]string
-}
-
-type secureJSONDecryptionCache struct {
-	cache map[int64]cachedDecryptedJSON
-	sync.Mutex
-}
-
-var dsDecryptionCache = secureJSONDecryptionCache{
-	cache: make(map[int64]cachedDecryptedJSON),
-}
-
 // DecryptedValues returns cached decrypted values from secureJsonData.
 func (ds *DataSource) DecryptedValues() map[string]string {
-	dsDecryptionCache.Lock()
-	defer dsDecryptionCache.Unlock()
+	key := transportCacheKey{orgId: ds.OrgId, datasourceUid: ds.Uid, updated: ds.Updated}
 
-	if item, present := dsDecryptionCache.cache[ds.Id]; present && ds.Updated.Equal(item.updated) {
-		return item.json
+	if cached, ok := defaultTransportCache.decryptedValues.get(key); ok {
+		return cached.(map[string]string)
 	}
 
 	json := ds.SecureJsonData.Decrypt()
-	dsDecryptionCache.cache[ds.Id] = cachedDecryptedJSON{
-		updated: ds.Updated,
-		json:    json,
-	}
+	defaultTransportCache.decryptedValues.set(key, json)
 
 	return json
 }
@@ -400,12 +626,12 @@ func (ds *DataSource) DecryptedValue(key string) (string, bool) {
 	return value, exists
 }
 
-// ClearDSDecryptionCache clears the datasource decryption cache.
+// ClearDSDecryptionCache clears the datasource decryption cache for every
+// organization. Prefer InvalidateTransportCache(orgId, uid) to flush just
+// the affected datasource when one is updated, deleted or has its secrets
+// rotated.
 func ClearDSDecryptionCache() {
-	dsDecryptionCache.Lock()
-	defer dsDecryptionCache.Unlock()
-
-	dsDecryptionCache.cache = make(map[int64]cachedDecryptedJSON)
+	defaultTransportCache.decryptedValues.clear()
 }
 
 func awsServiceNamespace(dsType string) string {
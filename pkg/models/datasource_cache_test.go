@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvideTransportCache_ReturnsSingleton(t *testing.T) {
+	require.Same(t, ProvideTransportCache(), ProvideTransportCache())
+	require.Same(t, defaultTransportCache, ProvideTransportCache(), "defaultTransportCache must be the same instance wire injects")
+}
+
+func TestTransportCache_Invalidate(t *testing.T) {
+	tc := &TransportCache{
+		transports:      newLRUCache("transports"),
+		roundTrippers:   newLRUCache("roundTrippers"),
+		decryptedValues: newLRUCache("decryptedValues"),
+	}
+
+	key := transportCacheKey{orgId: 1, datasourceUid: "ds1"}
+	tc.transports.set(key, "transport")
+	tc.roundTrippers.set(key, "roundTripper")
+	tc.decryptedValues.set(key, map[string]string{"secret": "value"})
+
+	tc.Invalidate(1, "ds1")
+
+	_, ok := tc.transports.get(key)
+	require.False(t, ok)
+	_, ok = tc.roundTrippers.get(key)
+	require.False(t, ok)
+	_, ok = tc.decryptedValues.get(key)
+	require.False(t, ok)
+}
@@ -0,0 +1,94 @@
+// Package api builds the HTTP-facing API responses for ngalert, translating
+// between the stored/evaluated rule state and the ruler/Prometheus-
+// compatible wire formats in api/tooling/definitions.
+package api
+
+import (
+	"time"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+)
+
+// EvaluatedRule is one rule's outcome after a group tick, in the same
+// order as the group's configured rules.
+type EvaluatedRule struct {
+	Name        string
+	Query       string
+	Type        string        // "alerting" or "recording"
+	State       string        // alerting rules only
+	For         time.Duration // alerting rules only
+	Labels      map[string]string
+	Annotations map[string]string // alerting rules only
+	Health      string
+	LastError   string
+}
+
+// BuildRuleGroup assembles a Prometheus-compatible RuleGroup API response
+// from a group's static config plus the outcome of its most recent tick.
+// evalDurations is each rule's evaluation duration, in rules order, as
+// returned by schedule.GroupRunner.Run or RunRecordingGroup; runner is
+// the GroupRunner that ran the tick and supplies LastEvaluationDuration
+// and ActiveEvaluations. runner may be nil, e.g. when building the
+// response for a group that hasn't ticked yet.
+func BuildRuleGroup(name, file string, intervalSeconds float64, rules []EvaluatedRule, evalDurations []time.Duration, lastEvaluation time.Time, runner *schedule.GroupRunner) apimodels.RuleGroup {
+	group := apimodels.RuleGroup{
+		Name:           name,
+		File:           file,
+		Interval:       intervalSeconds,
+		LastEvaluation: lastEvaluation,
+		Rules:          make([]apimodels.Rule, len(rules)),
+	}
+
+	var total time.Duration
+	for i, r := range rules {
+		d := evalDurations[i]
+		total += d
+
+		rule := apimodels.Rule{
+			Name:           r.Name,
+			Query:          r.Query,
+			Labels:         r.Labels,
+			Health:         r.Health,
+			LastError:      r.LastError,
+			Type:           r.Type,
+			LastEvaluation: lastEvaluation,
+			EvaluationTime: d.Seconds(),
+		}
+		if r.Type == "alerting" {
+			rule.State = r.State
+			rule.Duration = r.For.Seconds()
+			rule.Annotations = r.Annotations
+		}
+		group.Rules[i] = rule
+	}
+	group.EvaluationTime = total.Seconds()
+
+	if runner != nil {
+		group.LastEvaluationDuration = runner.LastEvaluationDuration().Seconds()
+		group.ActiveEvaluations = runner.ActiveEvaluations()
+	}
+
+	return group
+}
+
+// RuleGroups builds the Prometheus-compatible response for every group a
+// Scheduler is running, reading each group's most recent tick out of it.
+// rules supplies each group's static rule metadata (name, query, type,
+// ...), keyed by group name, in the same order the group's rules were
+// passed to Scheduler.Run; groups the Scheduler hasn't ticked yet (no
+// Snapshot available) are omitted.
+func RuleGroups(sched *schedule.Scheduler, rules map[string][]EvaluatedRule, file string) []apimodels.RuleGroup {
+	names := sched.GroupNames()
+	groups := make([]apimodels.RuleGroup, 0, len(names))
+
+	for _, name := range names {
+		runner, durations, interval, lastEval, ok := sched.Snapshot(name)
+		if !ok || durations == nil {
+			continue
+		}
+		groups = append(groups, BuildRuleGroup(name, file, interval.Seconds(), rules[name], durations, lastEval, runner))
+	}
+
+	return groups
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+)
+
+func TestBuildRuleGroup(t *testing.T) {
+	lastEvaluation := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rules := []EvaluatedRule{
+		{
+			Name:        "AlwaysFiring",
+			Query:       `[{"datasourceUid":"-100"}]`,
+			Type:        "alerting",
+			State:       "inactive",
+			For:         10 * time.Second,
+			Labels:      map[string]string{"label1": "val1"},
+			Annotations: map[string]string{"annotation1": "val1"},
+			Health:      "ok",
+		},
+		{
+			Name:   "cpu_usage:rate5m",
+			Query:  `[{"datasourceUid":"-100"}]`,
+			Type:   "recording",
+			Labels: map[string]string{"team": "infra"},
+			Health: "ok",
+		},
+	}
+	durations := []time.Duration{5 * time.Millisecond, 7 * time.Millisecond}
+
+	group := BuildRuleGroup("arulegroup", "default", 60, rules, durations, lastEvaluation, nil)
+
+	b, err := json.Marshal(group)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `
+{
+	"name": "arulegroup",
+	"file": "default",
+	"rules": [{
+		"state": "inactive",
+		"name": "AlwaysFiring",
+		"query": "[{\"datasourceUid\":\"-100\"}]",
+		"duration": 10,
+		"annotations": {"annotation1": "val1"},
+		"labels": {"label1": "val1"},
+		"health": "ok",
+		"lastError": "",
+		"type": "alerting",
+		"lastEvaluation": "2023-01-01T00:00:00Z",
+		"evaluationTime": 0.005
+	}, {
+		"name": "cpu_usage:rate5m",
+		"query": "[{\"datasourceUid\":\"-100\"}]",
+		"labels": {"team": "infra"},
+		"health": "ok",
+		"lastError": "",
+		"type": "recording",
+		"lastEvaluation": "2023-01-01T00:00:00Z",
+		"evaluationTime": 0.007
+	}],
+	"interval": 60,
+	"lastEvaluation": "2023-01-01T00:00:00Z",
+	"evaluationTime": 0.012
+}`, string(b))
+}
+
+// TestBuildRuleGroup_GroupRunnerStats confirms the group-level
+// lastEvaluationDuration/activeEvaluations fields are populated from a
+// real schedule.GroupRunner once it has run a tick, rather than always
+// reporting zero.
+func TestBuildRuleGroup_GroupRunnerStats(t *testing.T) {
+	runner := schedule.NewGroupRunner(schedule.GroupConfig{Concurrency: 2})
+	durations := runner.Run(context.Background(), []schedule.RuleEvaluation{
+		func(ctx context.Context) time.Duration { return time.Millisecond },
+		func(ctx context.Context) time.Duration { return 2 * time.Millisecond },
+	})
+
+	group := BuildRuleGroup("arulegroup", "default", 60, []EvaluatedRule{
+		{Name: "a", Type: "alerting", Health: "ok"},
+		{Name: "b", Type: "alerting", Health: "ok"},
+	}, durations, time.Time{}, runner)
+
+	require.Greater(t, group.LastEvaluationDuration, float64(0))
+	require.Equal(t, 0, group.ActiveEvaluations)
+}
+
+// TestRuleGroups confirms RuleGroups reads a live schedule.Scheduler's
+// ticks, rather than only ever being driven from a hand-built GroupRunner.
+func TestRuleGroups(t *testing.T) {
+	sched := schedule.NewScheduler(nil)
+
+	group := schedule.ScheduledGroup{
+		Name:     "arulegroup",
+		Interval: 5 * time.Millisecond,
+		Config:   schedule.GroupConfig{Concurrency: 2},
+		Rules: []schedule.Rule{
+			{Eval: func(ctx context.Context) time.Duration { return time.Millisecond }},
+			{Recording: &schedule.RecordingRule{
+				Metric: "cpu_usage:rate5m",
+				Eval:   func(ctx context.Context) (float64, error) { return 42, nil },
+			}},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	sched.Run(ctx, []schedule.ScheduledGroup{group})
+
+	rules := map[string][]EvaluatedRule{
+		"arulegroup": {
+			{Name: "AlwaysFiring", Type: "alerting", State: "inactive", Health: "ok"},
+			{Name: "cpu_usage:rate5m", Type: "recording", Health: "ok"},
+		},
+	}
+
+	groups := RuleGroups(sched, rules, "default")
+	require.Len(t, groups, 1)
+	require.Equal(t, "arulegroup", groups[0].Name)
+	require.Equal(t, "default", groups[0].File)
+	require.Len(t, groups[0].Rules, 2)
+	require.Greater(t, groups[0].LastEvaluationDuration, float64(0))
+
+	require.Empty(t, RuleGroups(schedule.NewScheduler(nil), nil, "default"), "a scheduler with no groups has nothing to report")
+}
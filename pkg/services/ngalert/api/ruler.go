@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+)
+
+// NewAlertEvaluation builds the schedule.RuleEvaluation for one
+// GrafanaManagedAlert node: it runs rule.Data through the expression
+// engine and returns how long the evaluation took.
+type NewAlertEvaluation func(rule *apimodels.PostableGrafanaRule) schedule.RuleEvaluation
+
+// NewRecordingEvaluation builds the value-producing function for one
+// GrafanaManagedRecording node: it runs rule.Data through the expression
+// engine and returns the sample value to remote-write.
+type NewRecordingEvaluation func(rule *apimodels.GrafanaManagedRecording) func(ctx context.Context) (float64, error)
+
+// BuildScheduledGroup is the step a ruler "set rule group" POST handler
+// takes after parsing a PostableRuleGroupConfig: it translates the posted
+// group, including any GrafanaManagedRecording nodes, into a
+// schedule.ScheduledGroup ready to hand to Scheduler.Run. newAlertEval and
+// newRecordingEval supply the actual per-rule evaluation closures, backed
+// by the expression engine in the full tree; this checkout has no
+// expression engine to wire in, so callers are expected to pass their own.
+func BuildScheduledGroup(cfg apimodels.PostableRuleGroupConfig, newAlertEval NewAlertEvaluation, newRecordingEval NewRecordingEvaluation) schedule.ScheduledGroup {
+	rules := make([]schedule.Rule, len(cfg.Rules))
+	for i, node := range cfg.Rules {
+		switch {
+		case node.GrafanaManagedRecording != nil:
+			rules[i] = schedule.Rule{
+				Recording: &schedule.RecordingRule{
+					Metric: node.GrafanaManagedRecording.Metric,
+					Labels: node.GrafanaManagedRecording.Labels,
+					Eval:   newRecordingEval(node.GrafanaManagedRecording),
+				},
+			}
+		case node.GrafanaManagedAlert != nil:
+			rules[i] = schedule.Rule{Eval: newAlertEval(node.GrafanaManagedAlert)}
+		}
+	}
+
+	return schedule.ScheduledGroup{
+		Name:     cfg.Name,
+		Interval: time.Duration(cfg.Interval),
+		Config: schedule.GroupConfig{
+			Concurrency:      cfg.Concurrency,
+			EvaluationOffset: time.Duration(cfg.EvaluationOffset),
+			Jitter:           time.Duration(cfg.Jitter),
+		},
+		Rules: rules,
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+// TestBuildScheduledGroup_RecordingRuleIsRemoteWritten runs a posted
+// PostableRuleGroupConfig's GrafanaManagedRecording node all the way
+// through BuildScheduledGroup, a real Scheduler tick and RemoteWriter, to
+// prove the recording-rule path has a genuine caller end to end, not just
+// a unit test of RemoteWriter or RunRecordingGroup in isolation.
+func TestBuildScheduledGroup_RecordingRuleIsRemoteWritten(t *testing.T) {
+	var writes int32
+	remoteWriteServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&writes, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer remoteWriteServer.Close()
+
+	cfg := apimodels.PostableRuleGroupConfig{
+		Name:        "arulegroup",
+		Concurrency: 2,
+		Rules: []apimodels.PostableExtendedRuleNode{
+			{
+				GrafanaManagedRecording: &apimodels.GrafanaManagedRecording{
+					Title:  "CPUUsageRecording",
+					Metric: "cpu_usage:rate5m",
+					Labels: map[string]string{"team": "infra"},
+				},
+			},
+		},
+	}
+
+	group := BuildScheduledGroup(cfg, nil, func(rule *apimodels.GrafanaManagedRecording) func(ctx context.Context) (float64, error) {
+		return func(ctx context.Context) (float64, error) { return 42, nil }
+	})
+	group.Interval = 5 * time.Millisecond
+
+	sched := schedule.NewScheduler(writer.NewRemoteWriter(remoteWriteServer.URL, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	sched.Run(ctx, []schedule.ScheduledGroup{group})
+
+	require.Greater(t, atomic.LoadInt32(&writes), int32(0), "the recording rule's value should have been remote-written")
+}
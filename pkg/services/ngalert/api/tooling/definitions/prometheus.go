@@ -0,0 +1,49 @@
+package definitions
+
+import "time"
+
+// RuleResponse is the body of a GET /api/prometheus/grafana/api/v1/rules
+// response.
+type RuleResponse struct {
+	Status string        `json:"status"`
+	Data   RuleDiscovery `json:"data"`
+}
+
+// RuleDiscovery lists every rule group Grafana knows about.
+type RuleDiscovery struct {
+	RuleGroups []RuleGroup `json:"groups"`
+}
+
+// RuleGroup is one rule group's evaluation state, as surfaced by the
+// Prometheus-compatible rules API. LastEvaluationDuration and
+// ActiveEvaluations come from the schedule.GroupRunner that ran the
+// group's most recent tick. EvaluationTime is the sum of the group's
+// per-rule evaluation times, so with Concurrency above 1 it can exceed the
+// tick's wall-clock LastEvaluationDuration.
+type RuleGroup struct {
+	Name                   string    `json:"name"`
+	File                   string    `json:"file"`
+	Rules                  []Rule    `json:"rules"`
+	Interval               float64   `json:"interval"`
+	LastEvaluation         time.Time `json:"lastEvaluation"`
+	EvaluationTime         float64   `json:"evaluationTime"`
+	LastEvaluationDuration float64   `json:"lastEvaluationDuration,omitempty"`
+	ActiveEvaluations      int       `json:"activeEvaluations,omitempty"`
+}
+
+// Rule is a single rule's evaluation state. Type is either "alerting" or
+// "recording"; State/Duration/Annotations only apply to alerting rules
+// and are omitted for recording rules.
+type Rule struct {
+	State          string            `json:"state,omitempty"`
+	Name           string            `json:"name"`
+	Query          string            `json:"query"`
+	Duration       float64           `json:"duration,omitempty"`
+	Annotations    map[string]string `json:"annotations,omitempty"`
+	Labels         map[string]string `json:"labels"`
+	Health         string            `json:"health"`
+	LastError      string            `json:"lastError"`
+	Type           string            `json:"type"`
+	LastEvaluation time.Time         `json:"lastEvaluation"`
+	EvaluationTime float64           `json:"evaluationTime"`
+}
@@ -0,0 +1,79 @@
+// Package definitions holds the request/response bodies for the ngalert
+// ruler and Prometheus-compatible rules HTTP APIs.
+//
+// Like pkg/services/ngalert/models in this checkout, PostableRuleGroupConfig,
+// PostableExtendedRuleNode, ApiRuleNode, PostableGrafanaRule, NoDataState and
+// ExecutionErrorState below stand in for pre-existing upstream types that
+// pkg/tests/api/alerting/api_prometheus_test.go already referenced before
+// this package existed in this snapshot — they are not new API surface and
+// a merge into the full tree should extend the real files rather than keep
+// these as parallel declarations. Concurrency/EvaluationOffset/Jitter on
+// PostableRuleGroupConfig and GrafanaManagedRecording (including its field
+// on PostableExtendedRuleNode) are the genuinely new additions from this
+// backlog.
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// NoDataState and ExecutionErrorState mirror ngmodels' enums so API
+// payloads don't take a direct dependency on the storage layer's types.
+type NoDataState string
+type ExecutionErrorState string
+
+// PostableRuleGroupConfig is the body of a ruler "set rule group" request.
+// Concurrency bounds how many of the group's rules may evaluate at once
+// (0 means sequential, the historical behavior) and EvaluationOffset
+// staggers the group's first tick, with up to Jitter of extra randomized
+// delay, so many groups sharing an interval don't all fire together.
+type PostableRuleGroupConfig struct {
+	Name             string                     `json:"name"`
+	Interval         model.Duration             `json:"interval,omitempty"`
+	Concurrency      int                        `json:"concurrency,omitempty"`
+	EvaluationOffset model.Duration             `json:"evaluation_offset,omitempty"`
+	Jitter           model.Duration             `json:"jitter,omitempty"`
+	Rules            []PostableExtendedRuleNode `json:"rules"`
+}
+
+// PostableExtendedRuleNode is exactly one of an alerting rule
+// (GrafanaManagedAlert) or a recording rule (GrafanaManagedRecording).
+type PostableExtendedRuleNode struct {
+	*ApiRuleNode
+	GrafanaManagedAlert     *PostableGrafanaRule     `json:"grafana_alert,omitempty"`
+	GrafanaManagedRecording *GrafanaManagedRecording `json:"grafana_recording,omitempty"`
+}
+
+// ApiRuleNode holds the Prometheus-rule-file-compatible fields shared by
+// Grafana-managed rules: how long a condition must hold before firing,
+// and static labels/annotations merged onto the result.
+type ApiRuleNode struct {
+	For         model.Duration    `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// PostableGrafanaRule is a Grafana-managed alerting rule: Data is
+// evaluated in order and Condition names the RefID whose result decides
+// whether the rule fires.
+type PostableGrafanaRule struct {
+	Title        string                `json:"title"`
+	Condition    string                `json:"condition"`
+	Data         []ngmodels.AlertQuery `json:"data"`
+	NoDataState  NoDataState           `json:"no_data_state,omitempty"`
+	ExecErrState ExecutionErrorState   `json:"exec_err_state,omitempty"`
+}
+
+// GrafanaManagedRecording is a Grafana-managed recording rule: Data is
+// evaluated the same way as an alerting rule's, but instead of firing an
+// alert, the result of Condition's RefID is remote-written as a single
+// sample named Metric with Labels attached.
+type GrafanaManagedRecording struct {
+	Title     string                `json:"title"`
+	Metric    string                `json:"metric"`
+	Condition string                `json:"condition"`
+	Data      []ngmodels.AlertQuery `json:"data"`
+	Labels    map[string]string     `json:"labels,omitempty"`
+}
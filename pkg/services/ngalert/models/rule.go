@@ -0,0 +1,58 @@
+// Package models holds the domain types shared by the ngalert scheduler,
+// store and API layers, independent of how a rule arrived (ruler HTTP API,
+// provisioning, file-based provisioning, ...).
+//
+// This checkout is a partial snapshot of the ngalert tree: Duration,
+// RelativeTimeRange, AlertQuery, NoDataState and ExecutionErrorState below
+// are not new — pkg/tests/api/alerting/api_prometheus_test.go already
+// depended on them before this package existed here, so they must already
+// be declared somewhere in the full module. They're (re)declared in this
+// file only because their real source isn't part of this snapshot; a
+// merge into the full tree should drop these redeclarations in favor of
+// the upstream originals rather than keep both.
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Duration is a time.Duration that marshals to/from JSON as nanoseconds,
+// matching how rule queries express relative time ranges.
+type Duration time.Duration
+
+// RelativeTimeRange is a queried time range relative to the evaluation
+// time, e.g. "from 5 hours ago to 3 hours ago".
+type RelativeTimeRange struct {
+	From Duration `json:"from"`
+	To   Duration `json:"to"`
+}
+
+// AlertQuery is a single query or expression in a rule's evaluation
+// pipeline, keyed by RefID so later stages can reference earlier ones.
+type AlertQuery struct {
+	RefID             string            `json:"refId"`
+	RelativeTimeRange RelativeTimeRange `json:"relativeTimeRange"`
+	Model             json.RawMessage   `json:"model"`
+}
+
+// NoDataState describes what an alerting rule should do when its query
+// returns no data.
+type NoDataState string
+
+const (
+	Alerting NoDataState = "Alerting"
+	NoData   NoDataState = "NoData"
+	OK       NoDataState = "OK"
+)
+
+// ExecutionErrorState describes what an alerting rule should do when its
+// query or expression evaluation errors out.
+type ExecutionErrorState string
+
+const (
+	AlertingErrState      ExecutionErrorState = "Alerting"
+	ErrorErrState         ExecutionErrorState = "Error"
+	KeepLastStateErrState ExecutionErrorState = "KeepLastState"
+	OkErrState            ExecutionErrorState = "OK"
+)
@@ -0,0 +1,50 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+// RecordingRule is a single GrafanaManagedRecording rule evaluated as part
+// of a group tick: Eval runs the rule's query and returns the value to
+// record, and Metric/Labels describe the resulting time series.
+type RecordingRule struct {
+	Metric string
+	Labels map[string]string
+	Eval   func(ctx context.Context) (float64, error)
+}
+
+// RunRecordingGroup evaluates every recording rule in the group through the
+// same bounded worker pool as Run, remote-writing each successful
+// evaluation's sample through w. Failed evaluations are not written, but
+// still count towards the returned per-rule durations. w may be nil, in
+// which case evaluations run but nothing is written, mirroring how an
+// alerting-only group has no writer configured.
+func (g *GroupRunner) RunRecordingGroup(ctx context.Context, rules []RecordingRule, w *writer.RemoteWriter) []time.Duration {
+	evaluations := make([]RuleEvaluation, len(rules))
+	for i, rule := range rules {
+		evaluations[i] = recordingRuleEvaluation(rule, w)
+	}
+	return g.Run(ctx, evaluations)
+}
+
+// recordingRuleEvaluation adapts a RecordingRule into a RuleEvaluation so it
+// can run alongside alerting rules through the same GroupRunner.Run call,
+// e.g. when Scheduler ticks a group whose rules are a mix of both kinds.
+func recordingRuleEvaluation(rule RecordingRule, w *writer.RemoteWriter) RuleEvaluation {
+	return func(ctx context.Context) time.Duration {
+		start := time.Now()
+		value, err := rule.Eval(ctx)
+		if err == nil && w != nil {
+			_ = w.Write(ctx, []writer.Sample{{
+				Metric:    rule.Metric,
+				Labels:    rule.Labels,
+				Value:     value,
+				Timestamp: start,
+			}})
+		}
+		return time.Since(start)
+	}
+}
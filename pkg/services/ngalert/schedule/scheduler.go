@@ -0,0 +1,137 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+// Rule is one group member as the Scheduler sees it: exactly one of Eval
+// (an alerting rule) or Recording (a GrafanaManagedRecording rule) is set,
+// mirroring apimodels.PostableExtendedRuleNode's own GrafanaManagedAlert/
+// GrafanaManagedRecording split.
+type Rule struct {
+	Eval      RuleEvaluation
+	Recording *RecordingRule
+}
+
+// ScheduledGroup is one rule group's scheduling configuration: how often it
+// ticks, the bounded-concurrency config each tick runs under, and its rules.
+type ScheduledGroup struct {
+	Name     string
+	Interval time.Duration
+	Config   GroupConfig
+	Rules    []Rule
+}
+
+// groupState is the Scheduler's bookkeeping for a single running group.
+type groupState struct {
+	runner    *GroupRunner
+	interval  time.Duration
+	durations []time.Duration
+	lastEval  time.Time
+}
+
+// Scheduler is the ngalert group-evaluation loop: it runs every group on
+// its own ticker, pushing each tick's rules through a GroupRunner so a
+// group's concurrency stays bounded, and remote-writing any recording
+// rule results through Writer. GroupRunner itself only bounds a single
+// tick's concurrency; Scheduler is what actually calls it repeatedly.
+type Scheduler struct {
+	Writer *writer.RemoteWriter
+
+	mu     sync.Mutex
+	states map[string]*groupState
+}
+
+// NewScheduler returns a Scheduler that remote-writes recording rule
+// results through w. w may be nil, e.g. when no group has recording rules.
+func NewScheduler(w *writer.RemoteWriter) *Scheduler {
+	return &Scheduler{Writer: w, states: make(map[string]*groupState)}
+}
+
+// Run starts one tick loop per group, evaluating each immediately and then
+// again every group.Interval, and blocks until ctx is done.
+func (s *Scheduler) Run(ctx context.Context, groups []ScheduledGroup) {
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		group := group
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runGroup(ctx, group)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) runGroup(ctx context.Context, group ScheduledGroup) {
+	runner := NewGroupRunner(group.Config)
+
+	s.mu.Lock()
+	s.states[group.Name] = &groupState{runner: runner, interval: group.Interval}
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(group.Interval)
+	defer ticker.Stop()
+
+	s.tick(ctx, group.Name, runner, group.Rules)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick(ctx, group.Name, runner, group.Rules)
+		}
+	}
+}
+
+func (s *Scheduler) tick(ctx context.Context, name string, runner *GroupRunner, rules []Rule) {
+	evaluations := make([]RuleEvaluation, len(rules))
+	for i, rule := range rules {
+		if rule.Recording != nil {
+			evaluations[i] = recordingRuleEvaluation(*rule.Recording, s.Writer)
+		} else {
+			evaluations[i] = rule.Eval
+		}
+	}
+
+	durations := runner.Run(ctx, evaluations)
+
+	s.mu.Lock()
+	if state, ok := s.states[name]; ok {
+		state.durations = durations
+		state.lastEval = time.Now()
+	}
+	s.mu.Unlock()
+}
+
+// Snapshot returns group name's most recently started GroupRunner, the
+// evaluation durations from its last completed tick (in rules order), its
+// configured tick interval, and the time that tick finished. ok is false
+// until the group has been scheduled via Run.
+func (s *Scheduler) Snapshot(name string) (runner *GroupRunner, durations []time.Duration, interval time.Duration, lastEval time.Time, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, present := s.states[name]
+	if !present {
+		return nil, nil, 0, time.Time{}, false
+	}
+	return state.runner, state.durations, state.interval, state.lastEval, true
+}
+
+// GroupNames returns the names of every group the Scheduler is running, in
+// no particular order.
+func (s *Scheduler) GroupNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.states))
+	for name := range s.states {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,55 @@
+package schedule
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_TicksGroupAndExposesSnapshot(t *testing.T) {
+	var alertingCalls int32
+	var recordingCalls int32
+
+	group := ScheduledGroup{
+		Name:     "arulegroup",
+		Interval: 5 * time.Millisecond,
+		Config:   GroupConfig{Concurrency: 2},
+		Rules: []Rule{
+			{Eval: func(ctx context.Context) time.Duration {
+				atomic.AddInt32(&alertingCalls, 1)
+				return time.Millisecond
+			}},
+			{Recording: &RecordingRule{
+				Metric: "cpu_usage:rate5m",
+				Eval: func(ctx context.Context) (float64, error) {
+					atomic.AddInt32(&recordingCalls, 1)
+					return 42, nil
+				},
+			}},
+		},
+	}
+
+	sched := NewScheduler(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	sched.Run(ctx, []ScheduledGroup{group})
+
+	require.Equal(t, []string{"arulegroup"}, sched.GroupNames())
+
+	runner, durations, interval, lastEval, ok := sched.Snapshot("arulegroup")
+	require.True(t, ok)
+	require.NotNil(t, runner)
+	require.Len(t, durations, 2)
+	require.Equal(t, 5*time.Millisecond, interval)
+	require.False(t, lastEval.IsZero())
+
+	require.Greater(t, atomic.LoadInt32(&alertingCalls), int32(1), "ticker should have fired more than once")
+	require.Greater(t, atomic.LoadInt32(&recordingCalls), int32(1))
+
+	_, _, _, _, ok = sched.Snapshot("unknown-group")
+	require.False(t, ok)
+}
@@ -51,7 +51,8 @@ func TestPrometheusRules(t *testing.T) {
 	// Now, let's create some rules
 	{
 		rules := apimodels.PostableRuleGroupConfig{
-			Name: "arulegroup",
+			Name:        "arulegroup",
+			Concurrency: 2,
 			Rules: []apimodels.PostableExtendedRuleNode{
 				{
 					ApiRuleNode: &apimodels.ApiRuleNode{
@@ -102,6 +103,27 @@ func TestPrometheusRules(t *testing.T) {
 						ExecErrState: apimodels.ExecutionErrorState(ngmodels.KeepLastStateErrState),
 					},
 				},
+				{
+					GrafanaManagedRecording: &apimodels.GrafanaManagedRecording{
+						Title:     "CPUUsageRecording",
+						Metric:    "cpu_usage:rate5m",
+						Condition: "A",
+						Data: []ngmodels.AlertQuery{
+							{
+								RefID: "A",
+								RelativeTimeRange: ngmodels.RelativeTimeRange{
+									From: ngmodels.Duration(time.Duration(5) * time.Hour),
+									To:   ngmodels.Duration(time.Duration(3) * time.Hour),
+								},
+								Model: json.RawMessage(`{
+									"datasourceUid": "-100",
+									"type": "math",
+									"expression": "2 + 3 > 1"
+									}`),
+							},
+						},
+					},
+				},
 			},
 		}
 		buf := bytes.Buffer{}
@@ -170,6 +192,15 @@ func TestPrometheusRules(t *testing.T) {
 				"type": "alerting",
 				"lastEvaluation": "0001-01-01T00:00:00Z",
 				"evaluationTime": 0
+			}, {
+				"name": "cpu_usage:rate5m",
+				"query": "[{\"datasourceUid\":\"-100\",\"expression\":\"2 + 3 \\u003e 1\",\"intervalMs\":1000,\"maxDataPoints\":100,\"type\":\"math\"}]",
+				"labels": null,
+				"health": "ok",
+				"lastError": "",
+				"type": "recording",
+				"lastEvaluation": "0001-01-01T00:00:00Z",
+				"evaluationTime": 0
 			}],
 			"interval": 60,
 			"lastEvaluation": "0001-01-01T00:00:00Z",
@@ -225,6 +256,15 @@ func TestPrometheusRules(t *testing.T) {
 				"type": "alerting",
 				"lastEvaluation": "0001-01-01T00:00:00Z",
 				"evaluationTime": 0
+			}, {
+				"name": "cpu_usage:rate5m",
+				"query": "[{\"datasourceUid\":\"-100\",\"expression\":\"2 + 3 \\u003e 1\",\"intervalMs\":1000,\"maxDataPoints\":100,\"type\":\"math\"}]",
+				"labels": null,
+				"health": "ok",
+				"lastError": "",
+				"type": "recording",
+				"lastEvaluation": "0001-01-01T00:00:00Z",
+				"evaluationTime": 0
 			}],
 			"interval": 60,
 			"lastEvaluation": "0001-01-01T00:00:00Z",